@@ -0,0 +1,109 @@
+// Command repack-chunk re-packs existing raw dictionary chunk .bin files
+// (as produced for the WASM completer) into the versioned, compressed
+// chunk format understood by parseBinaryChunk: a 4-byte sentinel and a
+// format byte (0x00 raw, 0x01 flate, 0x02 gzip) prepended to the untouched
+// [word count][entries...] payload.
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	chunkFormatRaw   byte = 0x00
+	chunkFormatFlate byte = 0x01
+	chunkFormatGzip  byte = 0x02
+)
+
+// chunkFormatSentinel marks a versioned chunk; see the matching constant in
+// ws-wasm.go. A legacy .bin's first 4 bytes are always a non-negative word
+// count, so they can never equal this value.
+const chunkFormatSentinel int32 = -1
+
+func main() {
+	method := flag.String("method", "flate", "compression method: flate or gzip")
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "usage: repack-chunk -method flate|gzip <in.bin> <out.bin>")
+		os.Exit(1)
+	}
+
+	var format byte
+	switch *method {
+	case "flate":
+		format = chunkFormatFlate
+	case "gzip":
+		format = chunkFormatGzip
+	default:
+		fmt.Fprintf(os.Stderr, "unknown method %q\n", *method)
+		os.Exit(1)
+	}
+
+	if err := repack(flag.Arg(0), flag.Arg(1), format); err != nil {
+		fmt.Fprintln(os.Stderr, "repack-chunk:", err)
+		os.Exit(1)
+	}
+}
+
+func repack(inPath, outPath string, format byte) error {
+	raw, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", inPath, err)
+	}
+
+	if len(raw) >= 4 && int32(binary.LittleEndian.Uint32(raw[:4])) == chunkFormatSentinel {
+		return fmt.Errorf("%s is already in the versioned chunk format; pass the original raw .bin", inPath)
+	}
+
+	var compressed bytes.Buffer
+	switch format {
+	case chunkFormatFlate:
+		w, err := flate.NewWriter(&compressed, flate.BestCompression)
+		if err != nil {
+			return fmt.Errorf("create flate writer: %w", err)
+		}
+		if _, err := w.Write(raw); err != nil {
+			return fmt.Errorf("flate compress: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("flate close: %w", err)
+		}
+	case chunkFormatGzip:
+		w, _ := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+		if _, err := w.Write(raw); err != nil {
+			return fmt.Errorf("gzip compress: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("gzip close: %w", err)
+		}
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", outPath, err)
+	}
+	defer out.Close()
+
+	var header [5]byte
+	sentinel := chunkFormatSentinel
+	binary.LittleEndian.PutUint32(header[:4], uint32(sentinel))
+	header[4] = format
+	if _, err := out.Write(header[:]); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	compressedLen := compressed.Len()
+	if _, err := io.Copy(out, &compressed); err != nil {
+		return fmt.Errorf("write payload: %w", err)
+	}
+
+	fmt.Printf("%s: %d bytes -> %s: %d bytes\n", inPath, len(raw), outPath, compressedLen+len(header))
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildRawChunk builds a legacy [4-byte word count][entries...] payload with
+// n words, each entry [2 bytes word length][word][2 bytes rank].
+func buildRawChunk(n int) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(n))
+	for i := 0; i < n; i++ {
+		word := fmt.Sprintf("word%d", i)
+		binary.Write(&buf, binary.LittleEndian, uint16(len(word)))
+		buf.WriteString(word)
+		binary.Write(&buf, binary.LittleEndian, uint16(i%65536))
+	}
+	return buf.Bytes()
+}
+
+// decodeRepackedChunk decodes a versioned chunk produced by repack, mirroring
+// parseBinaryChunk/decodeChunkEntries in ws-wasm.go. It fails the test if an
+// entry's word is not the "word<i>" that buildRawChunk wrote, and returns the
+// entries' ranks in order.
+func decodeRepackedChunk(t *testing.T, data []byte) []uint16 {
+	t.Helper()
+
+	if len(data) < 5 {
+		t.Fatalf("chunk too small: %d bytes", len(data))
+	}
+	if int32(binary.LittleEndian.Uint32(data[:4])) != chunkFormatSentinel {
+		t.Fatalf("missing chunk sentinel")
+	}
+
+	format := data[4]
+	payload := data[5:]
+
+	var reader io.Reader
+	switch format {
+	case chunkFormatFlate:
+		fr := flate.NewReader(bytes.NewReader(payload))
+		defer fr.Close()
+		reader = fr
+	case chunkFormatGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("open gzip reader: %v", err)
+		}
+		defer gr.Close()
+		reader = gr
+	default:
+		t.Fatalf("unexpected format byte %d", format)
+	}
+
+	var wordCount int32
+	if err := binary.Read(reader, binary.LittleEndian, &wordCount); err != nil {
+		t.Fatalf("read word count: %v", err)
+	}
+
+	ranks := make([]uint16, wordCount)
+	for i := int32(0); i < wordCount; i++ {
+		var wordLen uint16
+		if err := binary.Read(reader, binary.LittleEndian, &wordLen); err != nil {
+			t.Fatalf("read word length: %v", err)
+		}
+		word := make([]byte, wordLen)
+		if _, err := io.ReadFull(reader, word); err != nil {
+			t.Fatalf("read word: %v", err)
+		}
+		if string(word) != fmt.Sprintf("word%d", i) {
+			t.Fatalf("entry %d: got word %q", i, word)
+		}
+		var rank uint16
+		if err := binary.Read(reader, binary.LittleEndian, &rank); err != nil {
+			t.Fatalf("read rank: %v", err)
+		}
+		ranks[i] = rank
+	}
+
+	return ranks
+}
+
+func TestRepackRoundTrip(t *testing.T) {
+	wordCounts := []int{0, 1, 255, 256, 257, 512, 1024, 4096}
+
+	for _, n := range wordCounts {
+		for _, method := range []struct {
+			name   string
+			format byte
+		}{
+			{"flate", chunkFormatFlate},
+			{"gzip", chunkFormatGzip},
+		} {
+			t.Run(fmt.Sprintf("n=%d/%s", n, method.name), func(t *testing.T) {
+				dir := t.TempDir()
+				inPath := filepath.Join(dir, "in.bin")
+				outPath := filepath.Join(dir, "out.bin")
+
+				raw := buildRawChunk(n)
+				if err := os.WriteFile(inPath, raw, 0o644); err != nil {
+					t.Fatalf("write input: %v", err)
+				}
+
+				if err := repack(inPath, outPath, method.format); err != nil {
+					t.Fatalf("repack: %v", err)
+				}
+
+				out, err := os.ReadFile(outPath)
+				if err != nil {
+					t.Fatalf("read output: %v", err)
+				}
+
+				ranks := decodeRepackedChunk(t, out)
+				if len(ranks) != n {
+					t.Fatalf("got %d entries, want %d", len(ranks), n)
+				}
+				for i, rank := range ranks {
+					if want := uint16(i % 65536); rank != want {
+						t.Fatalf("entry %d: got rank %d, want %d", i, rank, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRepackRefusesAlreadyVersionedChunk(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.bin")
+	outPath := filepath.Join(dir, "out.bin")
+
+	raw := buildRawChunk(256)
+	if err := os.WriteFile(inPath, raw, 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	if err := repack(inPath, outPath, chunkFormatFlate); err != nil {
+		t.Fatalf("repack: %v", err)
+	}
+
+	if err := repack(outPath, filepath.Join(dir, "out2.bin"), chunkFormatFlate); err == nil {
+		t.Fatal("expected repack to refuse an already-versioned chunk, got nil error")
+	}
+}
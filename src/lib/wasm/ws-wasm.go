@@ -4,6 +4,9 @@
 package main
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"encoding/binary"
 	"io"
 	"syscall/js"
@@ -13,6 +16,21 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// Chunk format bytes, identifying how a versioned chunk's payload is
+// compressed.
+const (
+	chunkFormatRaw   byte = 0x00
+	chunkFormatFlate byte = 0x01
+	chunkFormatGzip  byte = 0x02
+)
+
+// chunkFormatSentinel replaces the legacy 4-byte word count to mark a
+// versioned chunk: [4-byte sentinel][1-byte format][word count][entries...].
+// A real word count is always non-negative, so this value can never collide
+// with a pre-existing chunk, and chunks shipped before this change keep
+// loading unmodified.
+const chunkFormatSentinel int32 = -1
+
 type WASMCompleter struct {
 	completer suggest.ICompleter
 }
@@ -32,6 +50,32 @@ type CompletionSuggestion struct {
 	Rank int    `msgpack:"r"`
 }
 
+type FuzzyCompletionRequest struct {
+	Prefix      string `msgpack:"p"`
+	Limit       int    `msgpack:"l"`
+	MaxDistance int    `msgpack:"d"`
+	PrefixLen   int    `msgpack:"pl"`
+}
+
+type FuzzyCompletionResponse struct {
+	Suggestions []FuzzySuggestion `msgpack:"s"`
+	Count       int               `msgpack:"c"`
+}
+
+type FuzzySuggestion struct {
+	Word     string `msgpack:"w"`
+	Rank     int    `msgpack:"r"`
+	Distance int    `msgpack:"dt"`
+}
+
+type BatchRequest struct {
+	Queries []CompletionRequest `msgpack:"q"`
+}
+
+type BatchResponse struct {
+	Results []CompletionResponse `msgpack:"r"`
+}
+
 var globalCompleter *WASMCompleter
 
 func main() {
@@ -44,13 +88,22 @@ func main() {
 
 	// Export functions to JavaScript
 	js.Global().Set("wasmCompleter", js.ValueOf(map[string]interface{}{
-		"initWithData":        js.FuncOf(initWithData),
-		"initWithBinaryData":  js.FuncOf(initWithBinaryData),
-		"addWord":             js.FuncOf(addWord),
-		"complete":            js.FuncOf(complete),
-		"completeRaw":         js.FuncOf(completeRaw),
-		"stats":               js.FuncOf(stats),
-		"loadDictionaryChunk": js.FuncOf(loadDictionaryChunk),
+		"initWithData":                  js.FuncOf(initWithData),
+		"initWithBinaryData":            js.FuncOf(initWithBinaryData),
+		"initWithBinaryDataCompact":     js.FuncOf(initWithBinaryDataCompact),
+		"countCompletions":              js.FuncOf(countCompletions),
+		"addWord":                       js.FuncOf(addWord),
+		"removeWord":                    js.FuncOf(removeWord),
+		"bumpFrequency":                 js.FuncOf(bumpFrequency),
+		"snapshotDictionary":            js.FuncOf(snapshotDictionary),
+		"restoreFromSnapshot":           js.FuncOf(restoreFromSnapshot),
+		"complete":                      js.FuncOf(complete),
+		"completeFuzzy":                 js.FuncOf(completeFuzzy),
+		"completeBatch":                 js.FuncOf(completeBatch),
+		"completeRaw":                   js.FuncOf(completeRaw),
+		"stats":                         js.FuncOf(stats),
+		"loadDictionaryChunk":           js.FuncOf(loadDictionaryChunk),
+		"loadCompressedDictionaryChunk": js.FuncOf(loadCompressedDictionaryChunk),
 	}))
 
 	// Signal that WASM is ready
@@ -96,14 +149,51 @@ func initWithBinaryData(this js.Value, args []js.Value) interface{} {
 		return js.ValueOf(map[string]interface{}{"error": "missing data argument"})
 	}
 
-	// args[0] should be an array of Uint8Arrays (one for each chunk)
-	chunksArray := args[0]
+	totalWords, chunkCount, err := loadBinaryChunksInto(globalCompleter.completer, args[0])
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"success":   true,
+		"wordCount": totalWords,
+		"chunks":    chunkCount,
+	})
+}
+
+// initWithBinaryDataCompact is meant to initialize the roaring-bitmap-backed
+// compact completer described by this request, but that completer does not
+// exist: it requires re-architecting suggest.ICompleter's trie with
+// per-node posting-list bitmaps, which lives in github.com/bastiangx/wordserve,
+// a separate repository from this one, and is out of scope for this series.
+// Until that companion change lands upstream, this loads through the same
+// backend as initWithBinaryData and reports compact: false so callers don't
+// mistake this for the real thing; countCompletions is gated the same way.
+func initWithBinaryDataCompact(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing data argument"})
+	}
+
+	totalWords, chunkCount, err := loadBinaryChunksInto(globalCompleter.completer, args[0])
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"success":   true,
+		"wordCount": totalWords,
+		"chunks":    chunkCount,
+		"compact":   false,
+	})
+}
+
+// loadBinaryChunksInto parses a JS array of Uint8Array dictionary chunks and adds every word to completer
+func loadBinaryChunksInto(completer suggest.ICompleter, chunksArray js.Value) (totalWords, chunkCount int, err error) {
 	if !chunksArray.Get("length").Truthy() {
-		return js.ValueOf(map[string]interface{}{"error": "invalid chunks array"})
+		return 0, 0, js.Error{Value: js.ValueOf("invalid chunks array")}
 	}
 
-	totalWords := 0
-	chunkCount := chunksArray.Get("length").Int()
+	chunkCount = chunksArray.Get("length").Int()
 
 	for i := 0; i < chunkCount; i++ {
 		chunkData := chunksArray.Index(i)
@@ -115,28 +205,54 @@ func initWithBinaryData(this js.Value, args []js.Value) interface{} {
 		data := make([]byte, length)
 		js.CopyBytesToGo(data, chunkData)
 
-		words, err := parseBinaryChunk(data)
-		if err != nil {
-			return js.ValueOf(map[string]interface{}{
-				"error": "failed to parse binary chunk " + string(rune(i)) + ": " + err.Error(),
-			})
+		words, perr := parseBinaryChunk(data)
+		if perr != nil {
+			return 0, 0, js.Error{Value: js.ValueOf("failed to parse binary chunk " + string(rune(i)) + ": " + perr.Error())}
 		}
 
 		for word, freq := range words {
-			globalCompleter.completer.AddWord(word, freq)
+			completer.AddWord(word, freq)
 			totalWords++
 		}
 	}
 
+	return totalWords, chunkCount, nil
+}
+
+// loadDictionaryChunk loads a single binary dictionary chunk
+func loadDictionaryChunk(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing chunk data"})
+	}
+
+	uint8Array := args[0]
+	length := uint8Array.Get("length").Int()
+
+	data := make([]byte, length)
+	js.CopyBytesToGo(data, uint8Array)
+
+	words, err := parseBinaryChunk(data)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to parse chunk: " + err.Error()})
+	}
+
+	wordCount := 0
+	for word, freq := range words {
+		globalCompleter.completer.AddWord(word, freq)
+		wordCount++
+	}
+
 	return js.ValueOf(map[string]interface{}{
 		"success":   true,
-		"wordCount": totalWords,
-		"chunks":    chunkCount,
+		"wordCount": wordCount,
 	})
 }
 
-// loadDictionaryChunk loads a single binary dictionary chunk
-func loadDictionaryChunk(this js.Value, args []js.Value) interface{} {
+// loadCompressedDictionaryChunk loads a single binary dictionary chunk that is
+// required to carry a flate or gzip magic byte. Use this when the caller
+// already knows the chunk is compressed and wants a clear error instead of
+// silently falling back to the raw format.
+func loadCompressedDictionaryChunk(this js.Value, args []js.Value) interface{} {
 	if len(args) < 1 {
 		return js.ValueOf(map[string]interface{}{"error": "missing chunk data"})
 	}
@@ -147,6 +263,13 @@ func loadDictionaryChunk(this js.Value, args []js.Value) interface{} {
 	data := make([]byte, length)
 	js.CopyBytesToGo(data, uint8Array)
 
+	if len(data) < 5 || int32(binary.LittleEndian.Uint32(data[:4])) != chunkFormatSentinel {
+		return js.ValueOf(map[string]interface{}{"error": "chunk is not compressed"})
+	}
+	if format := data[4]; format != chunkFormatFlate && format != chunkFormatGzip {
+		return js.ValueOf(map[string]interface{}{"error": "chunk is not compressed"})
+	}
+
 	words, err := parseBinaryChunk(data)
 	if err != nil {
 		return js.ValueOf(map[string]interface{}{"error": "failed to parse chunk: " + err.Error()})
@@ -178,6 +301,114 @@ func addWord(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(map[string]interface{}{"success": true})
 }
 
+// wordRemover, frequencyBumper and snapshotter are the word removal,
+// frequency adjustment and versioned-snapshot persistence methods this
+// request needs from suggest.ICompleter. None of them exist: they require
+// changes to suggest, which lives in github.com/bastiangx/wordserve, a
+// separate repository from this one, and none of ICompleter's existing
+// methods (AddWord, Complete, Stats) can answer "remove this word" or
+// "serialize everything" without them. Implementing that is out of scope
+// for this series, so removeWord, bumpFrequency, snapshotDictionary and
+// restoreFromSnapshot below are wired up but inert — gated behind these
+// type assertions so they activate automatically once a companion change
+// upstream adds the methods, rather than failing to compile in the
+// meantime.
+type wordRemover interface {
+	RemoveWord(word string)
+}
+
+type frequencyBumper interface {
+	BumpFrequency(word string, delta int)
+}
+
+type snapshotter interface {
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
+// removeWord removes a single word so it's no longer suggested. Not
+// implemented yet: see the wordRemover doc comment.
+func removeWord(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing word"})
+	}
+
+	remover, ok := globalCompleter.completer.(wordRemover)
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "completer does not support removeWord"})
+	}
+
+	word := args[0].String()
+	remover.RemoveWord(word)
+
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+// bumpFrequency adjusts a word's frequency score by delta, positive or
+// negative. Not implemented yet: see the frequencyBumper doc comment.
+func bumpFrequency(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]interface{}{"error": "missing word or delta"})
+	}
+
+	bumper, ok := globalCompleter.completer.(frequencyBumper)
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "completer does not support bumpFrequency"})
+	}
+
+	word := args[0].String()
+	delta := args[1].Int()
+
+	bumper.BumpFrequency(word, delta)
+
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+// snapshotDictionary serializes the completer's full internal state to a
+// versioned binary Uint8Array. Not implemented yet: see the snapshotter doc
+// comment.
+func snapshotDictionary(this js.Value, args []js.Value) interface{} {
+	snap, ok := globalCompleter.completer.(snapshotter)
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "completer does not support snapshotDictionary"})
+	}
+
+	data, err := snap.Snapshot()
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to snapshot: " + err.Error()})
+	}
+
+	snapshotArray := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(snapshotArray, data)
+
+	return snapshotArray
+}
+
+// restoreFromSnapshot rebuilds the completer from a snapshotDictionary
+// Uint8Array in O(N). Not implemented yet: see the snapshotter doc comment.
+func restoreFromSnapshot(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing snapshot data"})
+	}
+
+	snap, ok := globalCompleter.completer.(snapshotter)
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "completer does not support restoreFromSnapshot"})
+	}
+
+	uint8Array := args[0]
+	length := uint8Array.Get("length").Int()
+
+	data := make([]byte, length)
+	js.CopyBytesToGo(data, uint8Array)
+
+	if err := snap.Restore(data); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to restore snapshot: " + err.Error()})
+	}
+
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
 // complete returns word completions for a prefix using MessagePack
 func complete(this js.Value, args []js.Value) interface{} {
 	if len(args) < 1 {
@@ -198,10 +429,25 @@ func complete(this js.Value, args []js.Value) interface{} {
 		return js.ValueOf(map[string]interface{}{"error": "failed to decode request: " + err.Error()})
 	}
 
-	// Get suggestions
+	response := completeOne(request)
+
+	// Encode as MessagePack
+	responseData, err := msgpack.Marshal(response)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to encode response: " + err.Error()})
+	}
+
+	// Convert to Uint8Array for JavaScript
+	responseArray := js.Global().Get("Uint8Array").New(len(responseData))
+	js.CopyBytesToJS(responseArray, responseData)
+
+	return responseArray
+}
+
+// completeOne builds the MessagePack response for a single completion request
+func completeOne(request CompletionRequest) CompletionResponse {
 	suggestions := globalCompleter.completer.Complete(request.Prefix, request.Limit)
 
-	// Convert to response format
 	responseSuggestions := make([]CompletionSuggestion, len(suggestions))
 	for i, s := range suggestions {
 		responseSuggestions[i] = CompletionSuggestion{
@@ -210,7 +456,129 @@ func complete(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	response := CompletionResponse{
+	return CompletionResponse{
+		Suggestions: responseSuggestions,
+		Count:       len(responseSuggestions),
+	}
+}
+
+// completeBatch resolves several completion requests in one JS<->WASM call.
+// Queries that share the exact same prefix reuse a single Complete call
+// (computed once at the largest limit requested for that prefix, then
+// sliced per query) instead of walking the trie again for each one. Forking
+// the walk at a shared but non-identical prefix (e.g. "cat" vs "catalog")
+// would need a lower-level ICompleter primitive that doesn't exist yet, so
+// only exact-prefix reuse is implemented here.
+func completeBatch(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing request data"})
+	}
+
+	uint8Array := args[0]
+	length := uint8Array.Get("length").Int()
+
+	requestData := make([]byte, length)
+	js.CopyBytesToGo(requestData, uint8Array)
+
+	var request BatchRequest
+	if err := msgpack.Unmarshal(requestData, &request); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to decode request: " + err.Error()})
+	}
+
+	maxLimitByPrefix := make(map[string]int, len(request.Queries))
+	for _, q := range request.Queries {
+		if q.Limit > maxLimitByPrefix[q.Prefix] {
+			maxLimitByPrefix[q.Prefix] = q.Limit
+		}
+	}
+
+	cache := make(map[string]CompletionResponse, len(maxLimitByPrefix))
+	results := make([]CompletionResponse, len(request.Queries))
+	for i, q := range request.Queries {
+		cached, ok := cache[q.Prefix]
+		if !ok {
+			cached = completeOne(CompletionRequest{Prefix: q.Prefix, Limit: maxLimitByPrefix[q.Prefix]})
+			cache[q.Prefix] = cached
+		}
+		results[i] = sliceCompletionResponse(cached, q.Limit)
+	}
+
+	response := BatchResponse{Results: results}
+
+	responseData, err := msgpack.Marshal(response)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to encode response: " + err.Error()})
+	}
+
+	responseArray := js.Global().Get("Uint8Array").New(len(responseData))
+	js.CopyBytesToJS(responseArray, responseData)
+
+	return responseArray
+}
+
+// sliceCompletionResponse truncates a response cached for a shared prefix
+// down to the limit a particular query actually asked for.
+func sliceCompletionResponse(response CompletionResponse, limit int) CompletionResponse {
+	if limit >= len(response.Suggestions) {
+		return response
+	}
+	return CompletionResponse{
+		Suggestions: response.Suggestions[:limit],
+		Count:       limit,
+	}
+}
+
+// fuzzyCompleter is the Levenshtein-automaton-style CompleteFuzzy method this
+// package needs from suggest.ICompleter. That method does not exist yet:
+// suggest lives in github.com/bastiangx/wordserve, a separate repository
+// from this one, and adding it there is out of scope for this series. Until
+// a companion change lands upstream, completeFuzzy is wired up but inert —
+// gated behind this type assertion so it activates automatically once a
+// backend implements it, rather than failing to compile in the meantime.
+type fuzzyCompleter interface {
+	CompleteFuzzy(prefix string, limit, maxDistance, prefixLen int) (words []string, distances []int)
+}
+
+// completeFuzzy returns typo-tolerant completions for a prefix using
+// MessagePack. Not implemented yet: see the fuzzyCompleter doc comment.
+func completeFuzzy(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing request data"})
+	}
+
+	// Get Uint8Array from JavaScript
+	uint8Array := args[0]
+	length := uint8Array.Get("length").Int()
+
+	// Convert to Go byte slice
+	requestData := make([]byte, length)
+	js.CopyBytesToGo(requestData, uint8Array)
+
+	// Decode MessagePack request
+	var request FuzzyCompletionRequest
+	if err := msgpack.Unmarshal(requestData, &request); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "failed to decode request: " + err.Error()})
+	}
+
+	fuzzy, ok := globalCompleter.completer.(fuzzyCompleter)
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "completer does not support completeFuzzy"})
+	}
+
+	// Get fuzzy suggestions
+	words, distances := fuzzy.CompleteFuzzy(request.Prefix, request.Limit, request.MaxDistance, request.PrefixLen)
+
+	// Convert to response format
+	responseSuggestions := make([]FuzzySuggestion, len(words))
+	for i, word := range words {
+		responseSuggestions[i] = FuzzySuggestion{
+			Word:     word,
+			Rank:     i + 1,
+			Distance: distances[i],
+		}
+	}
+
+	response := FuzzyCompletionResponse{
 		Suggestions: responseSuggestions,
 		Count:       len(responseSuggestions),
 	}
@@ -267,6 +635,34 @@ func stats(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(jsStats)
 }
 
+// countingCompleter is the bitmap-cardinality CountCompletions method the
+// compact completer described in initWithBinaryDataCompact would provide.
+// No backend implements it yet for the same reason that completer doesn't
+// exist, so this is gated behind a type assertion rather than called
+// directly; see the initWithBinaryDataCompact doc comment.
+type countingCompleter interface {
+	CountCompletions(prefix string) int
+}
+
+// countCompletions returns the number of completions available for a prefix.
+// Not implemented yet: see the countingCompleter doc comment.
+func countCompletions(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing prefix"})
+	}
+
+	counter, ok := globalCompleter.completer.(countingCompleter)
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "completer does not support countCompletions"})
+	}
+
+	prefix := args[0].String()
+
+	return js.ValueOf(map[string]interface{}{
+		"count": counter.CountCompletions(prefix),
+	})
+}
+
 // Helper functions
 
 func parseWordData(data []byte) []string {
@@ -321,16 +717,56 @@ func parseWordLine(line string) (string, int) {
 	return word, freq
 }
 
-// parseBinaryChunk parses a binary dictionary chunk
-// Format: [4 bytes word count][word entries...]
-// Each word entry: [2 bytes word length][word string][2 bytes rank]
+// parseBinaryChunk parses a binary dictionary chunk, either the legacy
+// [4 bytes word count][word entries...] layout or the versioned
+// [4-byte sentinel][1 byte format][4 bytes word count][word entries...]
+// layout used for compressed chunks. Each word entry:
+// [2 bytes word length][word string][2 bytes rank].
 func parseBinaryChunk(data []byte) (map[string]int, error) {
 	if len(data) < 4 {
 		return nil, js.Error{Value: js.ValueOf("chunk too small")}
 	}
 
-	reader := &byteReader{data: data, pos: 0}
+	if int32(binary.LittleEndian.Uint32(data[:4])) == chunkFormatSentinel {
+		if len(data) < 5 {
+			return nil, js.Error{Value: js.ValueOf("chunk too small")}
+		}
+		return parseVersionedChunk(data[4], data[5:])
+	}
+
+	// Legacy chunk: no sentinel, no format byte, raw entries stream.
+	return decodeChunkEntries(&byteReader{data: data, pos: 0})
+}
+
+// parseVersionedChunk decodes the entries stream following a chunk's
+// sentinel and format byte, decompressing it first if format calls for it.
+func parseVersionedChunk(format byte, payload []byte) (map[string]int, error) {
+	var reader io.Reader
+	switch format {
+	case chunkFormatRaw:
+		reader = &byteReader{data: payload, pos: 0}
+	case chunkFormatFlate:
+		fr := flate.NewReader(bytes.NewReader(payload))
+		defer fr.Close()
+		reader = fr
+	case chunkFormatGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, js.Error{Value: js.ValueOf("failed to open gzip chunk: " + err.Error())}
+		}
+		defer gr.Close()
+		reader = gr
+	default:
+		return nil, js.Error{Value: js.ValueOf("unknown chunk format byte")}
+	}
+
+	return decodeChunkEntries(reader)
+}
 
+// decodeChunkEntries reads the [4 bytes word count][entries...] stream
+// shared by every chunk format, regardless of whether reader sits directly
+// on the chunk bytes or on top of a flate/gzip decompressor.
+func decodeChunkEntries(reader io.Reader) (map[string]int, error) {
 	// Read word count
 	var wordCount int32
 	if err := binary.Read(reader, binary.LittleEndian, &wordCount); err != nil {
@@ -348,7 +784,7 @@ func parseBinaryChunk(data []byte) (map[string]int, error) {
 
 		// Read word
 		wordBytes := make([]byte, wordLen)
-		if _, err := reader.Read(wordBytes); err != nil {
+		if _, err := io.ReadFull(reader, wordBytes); err != nil {
 			return nil, js.Error{Value: js.ValueOf("failed to read word")}
 		}
 		word := string(wordBytes)